@@ -0,0 +1,109 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+)
+
+// Backend abstracts how gitmap obtains the commit log for a repository.
+// The default, execBackend, shells out to the git executable found in
+// $PATH (or opts.GetGitCommandFunc, if set). GoGitBackend is a pure-Go
+// alternative that does not require git to be installed.
+type Backend interface {
+	// ShowCdup returns the result of "git rev-parse --show-cdup" for the
+	// repository, i.e. the relative path from the current directory to the
+	// top level of the repository.
+	ShowCdup(opts Options) (string, error)
+
+	// LogEntries returns the raw log output for the given options, in the
+	// same record format produced by the git log invocation below: records
+	// separated by "\x1e", fields within a record separated by "\x1f", and
+	// the changed-files list separated from the rest of the record by
+	// "\x1d".
+	LogEntries(opts Options) (string, error)
+}
+
+// execBackend is the original Backend implementation: it shells out to the
+// git executable.
+type execBackend struct{}
+
+func (execBackend) ShowCdup(opts Options) (string, error) {
+	return git(opts, "-C", opts.Repository, "rev-parse", "--show-cdup")
+}
+
+func (execBackend) LogEntries(opts Options) (string, error) {
+	args, err := logArgs(opts)
+	if err != nil {
+		return "", err
+	}
+	return git(opts, args...)
+}
+
+// logEntriesPipe is like LogEntries, but streams the raw log output
+// through a pipe instead of buffering it, and aborts the command if ctx is
+// done before the command finishes. It backs MapStream.
+func (execBackend) logEntriesPipe(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	if opts.GetGitCommandFunc == nil {
+		opts.GetGitCommandFunc = func(stdout, stderr io.Writer, args ...string) (Runner, error) {
+			cmd := exec.Command(gitExec, args...)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			return cmd, nil
+		}
+	}
+
+	args, err := logArgs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	var errBuf bytes.Buffer
+	cmd, err := opts.GetGitCommandFunc(pw, &errBuf, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(runErr(cmd, &errBuf))
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			pr.CloseWithError(ctx.Err())
+		case <-done:
+		}
+	}()
+
+	return pr, nil
+}
+
+// logArgs builds the argument list shared by LogEntries and
+// logEntriesPipe.
+func logArgs(opts Options) ([]string, error) {
+	args := NewGitArgs("-c", "diff.renames=0", "-c", "log.showSignature=0", "-C", opts.Repository, "log").
+		AddDashesAndList(
+			"--name-only",
+			"--no-merges",
+			`--format=format:%x1e%H%x1f%h%x1f%s%x1f%aN%x1f%aE%x1f%ai%x1f%ci%x1f%b%x1d`,
+		)
+	if err := args.AddDynamicArguments(opts.Revision); err != nil {
+		return nil, err
+	}
+	if len(opts.Pathspecs) > 0 {
+		args.AddDashesAndList("--")
+		if err := args.AddDynamicArguments(opts.Pathspecs...); err != nil {
+			return nil, err
+		}
+	}
+	return args.ToStrings(), nil
+}
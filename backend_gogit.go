@@ -0,0 +1,131 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"fmt"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// GoGitBackend is a Backend implementation built on go-git. It opens the
+// repository directly and walks commits in-process, so it works in
+// environments where the git executable is not available (ErrGitNotFound
+// no longer applies when this backend is used).
+type GoGitBackend struct{}
+
+func (GoGitBackend) ShowCdup(opts Options) (string, error) {
+	// go-git always opens from the repository root it is pointed at, so
+	// there is no cdup to report.
+	return "", nil
+}
+
+func (GoGitBackend) LogEntries(opts Options) (string, error) {
+	repo, err := gogit.PlainOpen(opts.Repository)
+	if err != nil {
+		return "", fmt.Errorf("go-git: open repository: %w", err)
+	}
+
+	var rev plumbing.Revision
+	if opts.Revision != "" {
+		rev = plumbing.Revision(opts.Revision)
+	} else {
+		rev = plumbing.Revision("HEAD")
+	}
+
+	hash, err := repo.ResolveRevision(rev)
+	if err != nil {
+		return "", fmt.Errorf("go-git: resolve revision %q: %w", opts.Revision, err)
+	}
+
+	commitIter, err := repo.Log(&gogit.LogOptions{From: *hash})
+	if err != nil {
+		return "", fmt.Errorf("go-git: log: %w", err)
+	}
+
+	var b strings.Builder
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			// --no-merges semantics.
+			return nil
+		}
+
+		filenames, err := changedPaths(c)
+		if err != nil {
+			return err
+		}
+		if len(filenames) == 0 {
+			return nil
+		}
+
+		subject, body, _ := strings.Cut(c.Message, "\n")
+		fmt.Fprintf(&b, "\x1e%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1d%s",
+			c.Hash.String(),
+			c.Hash.String()[:7],
+			subject,
+			c.Author.Name,
+			c.Author.Email,
+			c.Author.When.Format("2006-01-02 15:04:05 -0700"),
+			c.Committer.When.Format("2006-01-02 15:04:05 -0700"),
+			strings.TrimSpace(body),
+			strings.Join(filenames, "\n"),
+		)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("go-git: walk commits: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// changedPaths returns the paths changed by c relative to its first parent,
+// mirroring "git log --name-only" for a non-merge commit.
+func changedPaths(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		if action == merkletrie.Delete {
+			// change.To is the zero Entry for a deletion, so the path
+			// comes from change.From instead. "git log --name-only"
+			// lists deleted paths too, and Map/MapStream rely on seeing
+			// every touched path, so this must match.
+			filenames = append(filenames, change.From.Name)
+			continue
+		}
+		filenames = append(filenames, change.To.Name)
+	}
+	return filenames, nil
+}
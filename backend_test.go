@@ -0,0 +1,118 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// initTestRepo creates a temp repository with a few commits, including one
+// that deletes a file, so execBackend and GoGitBackend can be compared
+// against identical history.
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	run("init")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644)
+	run("add", "a.txt")
+	run("commit", "-m", "add a")
+
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644)
+	run("add", "b.txt")
+	run("commit", "-m", "add b")
+
+	run("rm", "a.txt")
+	run("commit", "-m", "remove a")
+
+	return dir
+}
+
+// backendFilenames returns the sorted, de-duplicated set of filenames a
+// Backend reports for the repository at dir.
+func backendFilenames(t *testing.T, b Backend, dir string) []string {
+	t.Helper()
+
+	opts := Options{
+		Repository: dir,
+		GetGitCommandFunc: func(stdout, stderr io.Writer, args ...string) (Runner, error) {
+			cmd := exec.Command("git", args...)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			return cmd, nil
+		},
+	}
+
+	out, err := b.LogEntries(opts)
+	if err != nil {
+		t.Fatalf("LogEntries: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range strings.Split(strings.Trim(out, "\n\x1e'"), "\x1e") {
+		lines := strings.Split(entry, "\x1d")
+		if len(lines) != 2 {
+			continue
+		}
+		for _, filename := range strings.Split(lines[1], "\n") {
+			filename = strings.TrimSpace(filename)
+			if filename == "" || seen[filename] {
+				continue
+			}
+			seen[filename] = true
+			names = append(names, filename)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestBackendsAgree checks that execBackend and GoGitBackend report the
+// same set of touched paths, including ones later deleted, for the same
+// repository.
+func TestBackendsAgree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in $PATH")
+	}
+
+	dir := initTestRepo(t)
+	want := []string{"a.txt", "b.txt"}
+
+	for _, tc := range []struct {
+		name    string
+		backend Backend
+	}{
+		{"exec", execBackend{}},
+		{"gogit", GoGitBackend{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := backendFilenames(t, tc.backend, dir)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxForgePages bounds how many pages a Forge implementation will
+// fetch from a single Commits call, so a very long history can't make
+// gitmap page forever against a live API.
+const maxForgePages = 100
+
+// Forge fetches the commits touching a path from a remote forge's REST API,
+// without needing a local clone of the repository.
+type Forge interface {
+	// Commits returns the commits affecting opts.Repository (treated as a
+	// path within the remote repository) at opts.Revision, newest first.
+	Commits(opts Options) ([]ForgeCommit, error)
+}
+
+// ForgeCommit is the subset of commit data gitmap needs, as reported by a
+// Forge.
+type ForgeCommit struct {
+	Hash        string
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string // as reported by the forge; see dateLayouts
+	CommitDate  string // as reported by the forge; see dateLayouts
+	Filenames   []string
+}
+
+// ForgeBackend adapts a Forge to the Backend interface, so Map can be
+// populated from a remote forge's REST API instead of a local git
+// invocation.
+type ForgeBackend struct {
+	Forge Forge
+}
+
+func (ForgeBackend) ShowCdup(opts Options) (string, error) {
+	// There is no local working copy, so there is nothing to cd up from.
+	return "", nil
+}
+
+func (b ForgeBackend) LogEntries(opts Options) (string, error) {
+	commits, err := b.Forge.Commits(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, c := range commits {
+		if len(c.Filenames) == 0 {
+			continue
+		}
+		subject, body, _ := strings.Cut(c.Message, "\n")
+		fmt.Fprintf(&out, "\x1e%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1d%s",
+			c.Hash,
+			abbreviate(c.Hash),
+			subject,
+			c.AuthorName,
+			c.AuthorEmail,
+			c.AuthorDate,
+			c.CommitDate,
+			strings.TrimSpace(body),
+			strings.Join(c.Filenames, "\n"),
+		)
+	}
+	return out.String(), nil
+}
+
+func abbreviate(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// HTTPCache is a minimal ETag-based cache for httpGetJSON: it remembers the
+// ETag and body returned for a URL, sends the ETag back as If-None-Match on
+// the next request for that URL, and reuses the cached body on a 304
+// response instead of re-fetching it. A nil *HTTPCache disables caching.
+// Share one HTTPCache across calls (e.g. across pagination requests, or
+// between a Forge and an HTTPInfoSource hitting the same host) to get
+// any benefit from it.
+type HTTPCache struct {
+	mu      sync.Mutex
+	entries map[string]httpCacheEntry
+}
+
+type httpCacheEntry struct {
+	etag string
+	body []byte
+}
+
+// NewHTTPCache returns an empty HTTPCache.
+func NewHTTPCache() *HTTPCache {
+	return &HTTPCache{entries: make(map[string]httpCacheEntry)}
+}
+
+func (c *HTTPCache) get(url string) (httpCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *HTTPCache) set(url string, e httpCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = e
+}
+
+// httpGetJSON issues a GET request against url, optionally bearing token as
+// a Bearer Authorization header, and decodes the response body as JSON
+// into v. stripPrefix, if non-empty, is trimmed from the response body
+// before decoding (used by GerritForge to drop the ")]}'" XSSI guard). If
+// cache is non-nil, a previously seen ETag for url is sent as
+// If-None-Match, and a 304 response is satisfied from the cached body.
+func httpGetJSON(client *http.Client, url, token, stripPrefix string, cache *HTTPCache, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	var cached httpCacheEntry
+	var haveCached bool
+	if cache != nil {
+		if cached, haveCached = cache.get(url); haveCached && cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if !haveCached {
+			return fmt.Errorf("gitmap: %s: got 304 with no cached response", url)
+		}
+		return json.Unmarshal(cached.body, v)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitmap: %s: unexpected status %s", url, resp.Status)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return err
+	}
+	body := bytes.TrimPrefix(buf.Bytes(), []byte(stripPrefix))
+
+	if cache != nil {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			cache.set(url, httpCacheEntry{etag: etag, body: body})
+		}
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// gerritXSSIPrefix is prepended by Gerrit to every JSON response to guard
+// against cross-site script inclusion; it must be stripped before the body
+// can be decoded as JSON.
+const gerritXSSIPrefix = ")]}'"
+
+// GerritForge is a Forge implementation backed by the Gerrit REST API.
+type GerritForge struct {
+	// BaseURL is the Gerrit host, e.g. https://gerrit.example.com.
+	BaseURL string
+
+	// Token, if set, is sent as a bearer token (Gerrit also supports
+	// basic/digest auth, which callers can set up via HTTPClient instead).
+	Token string
+
+	HTTPClient *http.Client
+
+	// Cache, if set, is consulted for an ETag on every page request.
+	Cache *HTTPCache
+}
+
+type gerritCommitInfo struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Author  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Date  string `json:"date"`
+	} `json:"author"`
+	Committer struct {
+		Date string `json:"date"`
+	} `json:"committer"`
+}
+
+type gerritChangeInfo struct {
+	CurrentRevision string                        `json:"current_revision"`
+	Revisions       map[string]gerritRevisionInfo `json:"revisions"`
+
+	// MoreChanges is set by Gerrit on the last change of a page when
+	// further pages exist, per the "_more_changes" REST API convention.
+	MoreChanges bool `json:"_more_changes"`
+}
+
+type gerritRevisionInfo struct {
+	Commit gerritCommitInfo           `json:"commit"`
+	Files  map[string]json.RawMessage `json:"files"`
+}
+
+// gerritPageSize is the "n=" limit used for each Commits page, the
+// maximum Gerrit itself allows per request.
+const gerritPageSize = 100
+
+func (f GerritForge) Commits(opts Options) ([]ForgeCommit, error) {
+	query := fmt.Sprintf("file:%s", opts.Repository)
+	if opts.Revision != "" {
+		query += fmt.Sprintf(" branch:%s", opts.Revision)
+	}
+
+	var changes []gerritChangeInfo
+	for page := 0; page < maxForgePages; page++ {
+		changesURL := fmt.Sprintf("%s/changes/?q=%s&o=CURRENT_REVISION&o=CURRENT_COMMIT&o=CURRENT_FILES&n=%d&S=%d",
+			f.BaseURL, url.QueryEscape(query), gerritPageSize, page*gerritPageSize)
+
+		var batch []gerritChangeInfo
+		if err := httpGetJSON(f.HTTPClient, changesURL, f.Token, gerritXSSIPrefix, f.Cache, &batch); err != nil {
+			return nil, fmt.Errorf("gerrit: query changes: %w", err)
+		}
+		changes = append(changes, batch...)
+
+		more := len(batch) > 0 && batch[len(batch)-1].MoreChanges
+		if !more {
+			break
+		}
+		if page == maxForgePages-1 && opts.Logf != nil {
+			opts.Logf("gerrit: %s: stopped paginating %q at %d pages, history may be truncated",
+				f.BaseURL, query, maxForgePages)
+		}
+	}
+
+	commits := make([]ForgeCommit, 0, len(changes))
+	for _, ch := range changes {
+		rev, ok := ch.Revisions[ch.CurrentRevision]
+		if !ok {
+			continue
+		}
+
+		filenames := make([]string, 0, len(rev.Files))
+		for path := range rev.Files {
+			filenames = append(filenames, path)
+		}
+
+		commits = append(commits, ForgeCommit{
+			Hash:        rev.Commit.Commit,
+			Message:     rev.Commit.Message,
+			AuthorName:  rev.Commit.Author.Name,
+			AuthorEmail: rev.Commit.Author.Email,
+			AuthorDate:  rev.Commit.Author.Date,
+			CommitDate:  rev.Commit.Committer.Date,
+			Filenames:   filenames,
+		})
+	}
+
+	return commits, nil
+}
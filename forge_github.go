@@ -0,0 +1,107 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GitHubForge is a Forge implementation backed by the GitHub REST API.
+type GitHubForge struct {
+	Owner string
+	Repo  string
+
+	// BaseURL defaults to https://api.github.com, override for GitHub
+	// Enterprise.
+	BaseURL string
+
+	// Token, if set, is sent as a bearer token.
+	Token string
+
+	HTTPClient *http.Client
+
+	// Cache, if set, is consulted for an ETag on every page request.
+	Cache *HTTPCache
+}
+
+type githubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Date string `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+	Files []struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+}
+
+func (f GitHubForge) baseURL() string {
+	if f.BaseURL != "" {
+		return f.BaseURL
+	}
+	return "https://api.github.com"
+}
+
+func (f GitHubForge) Commits(opts Options) ([]ForgeCommit, error) {
+	var list []githubCommit
+	for page := 1; page <= maxForgePages; page++ {
+		listURL := fmt.Sprintf("%s/repos/%s/%s/commits?path=%s&per_page=100&page=%d",
+			f.baseURL(), f.Owner, f.Repo, url.QueryEscape(opts.Repository), page)
+		if opts.Revision != "" {
+			listURL += "&sha=" + url.QueryEscape(opts.Revision)
+		}
+
+		var batch []githubCommit
+		if err := httpGetJSON(f.HTTPClient, listURL, f.Token, "", f.Cache, &batch); err != nil {
+			return nil, fmt.Errorf("github: list commits: %w", err)
+		}
+		list = append(list, batch...)
+		if len(batch) < 100 {
+			break
+		}
+		if page == maxForgePages && opts.Logf != nil {
+			opts.Logf("github: %s/%s: stopped paginating %s at %d pages, history may be truncated",
+				f.Owner, f.Repo, opts.Repository, maxForgePages)
+		}
+	}
+
+	commits := make([]ForgeCommit, 0, len(list))
+	for _, lc := range list {
+		// The list endpoint does not report changed files, so fetch each
+		// commit individually.
+		var full githubCommit
+		commitURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s", f.baseURL(), f.Owner, f.Repo, lc.SHA)
+		if err := httpGetJSON(f.HTTPClient, commitURL, f.Token, "", f.Cache, &full); err != nil {
+			return nil, fmt.Errorf("github: get commit %s: %w", lc.SHA, err)
+		}
+
+		filenames := make([]string, len(full.Files))
+		for i, fl := range full.Files {
+			filenames[i] = fl.Filename
+		}
+
+		commits = append(commits, ForgeCommit{
+			Hash:        full.SHA,
+			Message:     full.Commit.Message,
+			AuthorName:  full.Commit.Author.Name,
+			AuthorEmail: full.Commit.Author.Email,
+			AuthorDate:  full.Commit.Author.Date,
+			CommitDate:  full.Commit.Committer.Date,
+			Filenames:   filenames,
+		})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GitLabForge is a Forge implementation backed by the GitLab REST API.
+type GitLabForge struct {
+	// ProjectID is the numeric or URL-encoded path/namespace project ID.
+	ProjectID string
+
+	// BaseURL defaults to https://gitlab.com/api/v4, override for
+	// self-hosted instances.
+	BaseURL string
+
+	// Token, if set, is sent as a bearer token.
+	Token string
+
+	HTTPClient *http.Client
+
+	// Cache, if set, is consulted for an ETag on every page request.
+	Cache *HTTPCache
+}
+
+type gitlabCommit struct {
+	ID            string `json:"id"`
+	Message       string `json:"message"`
+	AuthorName    string `json:"author_name"`
+	AuthorEmail   string `json:"author_email"`
+	AuthoredDate  string `json:"authored_date"`
+	CommittedDate string `json:"committed_date"`
+}
+
+type gitlabDiff struct {
+	NewPath string `json:"new_path"`
+	OldPath string `json:"old_path"`
+}
+
+func (f GitLabForge) baseURL() string {
+	if f.BaseURL != "" {
+		return f.BaseURL
+	}
+	return "https://gitlab.com/api/v4"
+}
+
+func (f GitLabForge) Commits(opts Options) ([]ForgeCommit, error) {
+	var list []gitlabCommit
+	for page := 1; page <= maxForgePages; page++ {
+		listURL := fmt.Sprintf("%s/projects/%s/repository/commits?path=%s&per_page=100&page=%d",
+			f.baseURL(), url.PathEscape(f.ProjectID), url.QueryEscape(opts.Repository), page)
+		if opts.Revision != "" {
+			listURL += "&ref_name=" + url.QueryEscape(opts.Revision)
+		}
+
+		var batch []gitlabCommit
+		if err := httpGetJSON(f.HTTPClient, listURL, f.Token, "", f.Cache, &batch); err != nil {
+			return nil, fmt.Errorf("gitlab: list commits: %w", err)
+		}
+		list = append(list, batch...)
+		if len(batch) < 100 {
+			break
+		}
+		if page == maxForgePages && opts.Logf != nil {
+			opts.Logf("gitlab: project %s: stopped paginating %s at %d pages, history may be truncated",
+				f.ProjectID, opts.Repository, maxForgePages)
+		}
+	}
+
+	commits := make([]ForgeCommit, 0, len(list))
+	for _, c := range list {
+		diffURL := fmt.Sprintf("%s/projects/%s/repository/commits/%s/diff",
+			f.baseURL(), url.PathEscape(f.ProjectID), c.ID)
+
+		var diffs []gitlabDiff
+		if err := httpGetJSON(f.HTTPClient, diffURL, f.Token, "", f.Cache, &diffs); err != nil {
+			return nil, fmt.Errorf("gitlab: diff commit %s: %w", c.ID, err)
+		}
+
+		filenames := make([]string, len(diffs))
+		for i, d := range diffs {
+			filenames[i] = d.NewPath
+		}
+
+		commits = append(commits, ForgeCommit{
+			Hash:        c.ID,
+			Message:     c.Message,
+			AuthorName:  c.AuthorName,
+			AuthorEmail: c.AuthorEmail,
+			AuthorDate:  c.AuthoredDate,
+			CommitDate:  c.CommittedDate,
+			Filenames:   filenames,
+		})
+	}
+
+	return commits, nil
+}
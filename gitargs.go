@@ -0,0 +1,67 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// dynArgPattern matches values that are safe to pass as a "dynamic"
+// argument to git, i.e. user- or caller-supplied values such as
+// opts.Revision. It rejects anything starting with a dash, which would
+// otherwise be interpreted by git as a flag (e.g. a revision of
+// "--upload-pack=..." being executed rather than treated as a ref).
+var dynArgPattern = regexp.MustCompile(`^[^-].*$`)
+
+// GitArgs is a safe builder for git command-line arguments. It is
+// modelled on Gitea's git.NewCommand: cmdArgs are literals supplied by
+// gitmap itself and are trusted as-is, while dynArgs come from callers
+// (such as Options.Revision) and are validated before being accepted.
+// This replaces building the argument list with fmt.Sprintf followed by
+// strings.Fields, which mis-tokenizes (or, worse, lets a caller inject a
+// git flag via) a revision containing spaces or a leading dash.
+//
+// GitArgs is exported so callers writing a custom Options.GetGitCommandFunc
+// can build their own git invocations with the same guarantees gitmap's
+// own backends rely on.
+type GitArgs struct {
+	args []string
+}
+
+// NewGitArgs starts a GitArgs builder with a set of known-safe literal
+// arguments.
+func NewGitArgs(cmdArgs ...string) *GitArgs {
+	return &GitArgs{args: append([]string{}, cmdArgs...)}
+}
+
+// AddDashesAndList appends known-safe literal arguments, typically flags
+// such as "--name-only" or a --format=... string built by gitmap itself.
+func (a *GitArgs) AddDashesAndList(cmdArgs ...string) *GitArgs {
+	a.args = append(a.args, cmdArgs...)
+	return a
+}
+
+// AddDynamicArguments validates and appends one or more caller-supplied
+// values, e.g. a revision. It returns an error if any value looks like a
+// git flag (starts with "-") rather than a plain argument.
+func (a *GitArgs) AddDynamicArguments(dynArgs ...string) error {
+	for _, v := range dynArgs {
+		if v == "" {
+			continue
+		}
+		if !dynArgPattern.MatchString(v) {
+			return fmt.Errorf("gitmap: invalid argument %q: must not start with a dash", v)
+		}
+		a.args = append(a.args, v)
+	}
+	return nil
+}
+
+// ToStrings returns the built argument list.
+func (a *GitArgs) ToStrings() []string {
+	return a.args
+}
@@ -7,6 +7,7 @@ package gitmap
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,7 +37,48 @@ type GitRepo struct {
 	TopLevelAbsPath string
 
 	// The files in this Git repository.
+	//
+	// Files is mutated in place by Update and UpdateFromPushPayload, so a
+	// reader that may run concurrently with those should go through
+	// Snapshot or Get rather than ranging over Files directly.
 	Files GitMap
+
+	// Changes, if set, receives one FileChange per file touched by a call
+	// to Update or UpdateFromPushPayload. Sends are non-blocking: a
+	// consumer that isn't keeping up misses events rather than stalling
+	// the update.
+	Changes chan<- FileChange
+
+	// mu guards Files against concurrent Update/UpdateFromPushPayload
+	// calls and reads via Snapshot/Get.
+	mu sync.RWMutex
+
+	// opts are the Options this GitRepo was built from, retained so
+	// Update can re-run a scoped log query with the same Backend and
+	// Overlays.
+	opts Options
+}
+
+// Get returns the GitInfo for filename, if present. It is safe to call
+// concurrently with Update and UpdateFromPushPayload.
+func (r *GitRepo) Get(filename string) (*GitInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	gi, ok := r.Files[filename]
+	return gi, ok
+}
+
+// Snapshot returns a copy of Files, safe to range over even while Update or
+// UpdateFromPushPayload may be running concurrently.
+func (r *GitRepo) Snapshot() GitMap {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snap := make(GitMap, len(r.Files))
+	for filename, gi := range r.Files {
+		giCopy := *gi
+		snap[filename] = &giCopy
+	}
+	return snap
 }
 
 // GitMap maps filenames to Git revision information.
@@ -71,9 +114,47 @@ type Options struct {
 	Repository        string // Path to the repository to map
 	Revision          string // Use blank or HEAD for the currently active revision
 	GetGitCommandFunc func(stdout, stderr io.Writer, args ...string) (Runner, error)
+
+	// Backend selects how the commit log is obtained. It defaults to
+	// execBackend, which shells out to the git executable. Set it to
+	// GoGitBackend{} to use the pure-Go implementation instead, which does
+	// not require git to be installed.
+	Backend Backend
+
+	// Overlays is a chain of supplementary GitInfo sources, consulted in
+	// order for each file after the git log has been parsed. It defaults
+	// to a single FileInfoSource pointed at
+	// "<parent of Repository>/assets/git-info/contentGitInfo.json", which
+	// preserves gitmap's original behaviour.
+	Overlays []InfoSource
+
+	// Pathspecs, if non-empty, restricts the log to these paths (passed to
+	// git as "-- <pathspecs>"). GitRepo.Update uses this to limit a log
+	// query to the files a push touched, rather than walking all of
+	// Revision's history. Only execBackend applies it; GoGitBackend and
+	// ForgeBackend ignore Pathspecs and return entries for all paths.
+	Pathspecs []string
+
+	// Context, if set, bounds MapStream: the consumer can stop the
+	// underlying log command and abandon the stream early by cancelling
+	// it. Map also accepts it, but since Map waits for the whole result,
+	// cancelling only shortens how long it waits before returning
+	// ctx.Err().
+	Context context.Context
+
+	// Logf, if set, receives diagnostic messages that used to be written
+	// straight to stdout, such as an overlay file that failed to load.
+	// It is never called with data the caller must act on; Map and
+	// MapStream still return a hard error for anything that should abort
+	// the call.
+	Logf func(format string, args ...interface{})
 }
 
-// Map creates a GitRepo with a file map from the given options.
+// Map creates a GitRepo with a file map from the given options. It is a
+// thin collector over MapStream: it drains the stream into a GitMap and
+// waits for the result, so cancelling opts.Context only shortens how long
+// it waits before returning ctx.Err(), rather than offering the early-exit
+// behaviour MapStream gives a channel consumer.
 func Map(opts Options) (*GitRepo, error) {
 	if opts.GetGitCommandFunc == nil {
 		opts.GetGitCommandFunc = func(stdout, stderr io.Writer, args ...string) (Runner, error) {
@@ -83,81 +164,41 @@ func Map(opts Options) (*GitRepo, error) {
 			return cmd, nil
 		}
 	}
-
-	m := make(GitMap)
-
-	parentDir := filepath.Dir(opts.Repository)
-	targetPath := filepath.Join(parentDir, "assets", "git-info", "contentGitInfo.json")
-	gim, err := ReadJSONFile(targetPath)
-	if err != nil {
-		fmt.Printf("targetPath: %s %s\n", targetPath, err)
+	if opts.Backend == nil {
+		opts.Backend = execBackend{}
+	}
+	if opts.Overlays == nil {
+		targetPath := filepath.Join(filepath.Dir(opts.Repository), "assets", "git-info", "contentGitInfo.json")
+		opts.Overlays = []InfoSource{&FileInfoSource{Filename: targetPath, Logf: opts.Logf}}
 	}
 
-	// First get the top level repo path
+	// First get the top level repo path.
 	absRepoPath, err := filepath.Abs(opts.Repository)
 	if err != nil {
 		return nil, err
 	}
 
-	out, err := git(opts, "-C", opts.Repository, "rev-parse", "--show-cdup")
+	cdupOut, err := opts.Backend.ShowCdup(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	cdUp := strings.TrimSpace(string(out))
+	cdUp := strings.TrimSpace(cdupOut)
 	topLevelPath := filepath.ToSlash(filepath.Join(absRepoPath, cdUp))
 
-	gitLogArgs := strings.Fields(fmt.Sprintf(
-		`--name-only --no-merges --format=format:%%x1e%%H%%x1f%%h%%x1f%%s%%x1f%%aN%%x1f%%aE%%x1f%%ai%%x1f%%ci%%x1f%%b%%x1d %s`,
-		opts.Revision,
-	))
-
-	gitLogArgs = append([]string{"-c", "diff.renames=0", "-c", "log.showSignature=0", "-C", opts.Repository, "log"}, gitLogArgs...)
-	out, err = git(opts, gitLogArgs...)
-	if err != nil {
+	m := make(GitMap)
+	fileCh, errc := MapStream(opts)
+	for f := range fileCh {
+		m[f.Filename] = f.Info
+	}
+	if err := <-errc; err != nil {
 		return nil, err
 	}
-
-	entriesStr := strings.Trim(out, "\n\x1e'")
-	entries := strings.Split(entriesStr, "\x1e")
-
-	for _, e := range entries {
-		lines := strings.Split(e, "\x1d")
-		filenames := strings.Split(lines[1], "\n")
-
-		for _, filename := range filenames {
-
-			gitInfo, err := toGitInfo(lines[0])
-			if err != nil {
-				return nil, err
-			}
-			filename := strings.TrimSpace(filename)
-			if filename == "" {
-				continue
-			}
-			if originGi, ok := m[filename]; !ok {
-				m[filename] = gitInfo
-			} else {
-				originGi.CreateDate = gitInfo.AuthorDate
-				originGi.MergeCreateDate = gitInfo.AuthorDate
-			}
-
-			calcInfo := m[filename]
-			if jsonInfo, exists := gim[filename]; exists {
-				calcInfo.FromGetJson = &jsonInfo
-
-				if jsonInfo.CreateDate.Before(calcInfo.CreateDate) {
-					calcInfo.MergeCreateDate = jsonInfo.CreateDate
-				}
-				if jsonInfo.AuthorDate.After(calcInfo.AuthorDate) {
-					calcInfo.MergeUpdateDate = jsonInfo.AuthorDate
-				}
-			}
-			calcInfo.Year = calcInfo.MergeCreateDate.Format("2006")
-		}
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return nil, opts.Context.Err()
 	}
 
-	return &GitRepo{Files: m, TopLevelAbsPath: topLevelPath}, nil
+	return &GitRepo{Files: m, TopLevelAbsPath: topLevelPath, opts: opts}, nil
 }
 
 // FileExists checks if a file exists.
@@ -192,28 +233,59 @@ func git(opts Options, args ...string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	err = cmd.Run()
-	if err != nil {
-		if ee, ok := err.(*exec.Error); ok {
-			if ee.Err == exec.ErrNotFound {
-				return "", ErrGitNotFound
-			}
-		}
-		return "", errors.New(strings.TrimSpace(errBuff.String()))
+	if err := runErr(cmd, &errBuff); err != nil {
+		return "", err
 	}
 	return outBuff.String(), nil
 }
 
+// runErr runs cmd and translates its error the way git() always has: a
+// missing git executable becomes ErrGitNotFound, and any other failure
+// becomes git's own trimmed stderr rather than the opaque "exit status N"
+// from cmd.Run(). logEntriesPipe uses this too, so a streaming Map sees
+// the same errors a buffered one would.
+func runErr(cmd Runner, errBuf *bytes.Buffer) error {
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ee, ok := err.(*exec.Error); ok && ee.Err == exec.ErrNotFound {
+		return ErrGitNotFound
+	}
+	return errors.New(strings.TrimSpace(errBuf.String()))
+}
+
+// dateLayouts are tried, in order, by parseGitDate. The first is what
+// execBackend and GoGitBackend emit; the rest accommodate the native
+// timestamp formats of the Forge implementations, which are not
+// reformatted before reaching toGitInfo.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05 -0700",     // git log --format=%ai/%ci, go-git
+	time.RFC3339,                    // GitHub, GitLab
+	"2006-01-02 15:04:05.000000000", // Gerrit
+}
+
+func parseGitDate(s string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("gitmap: parse date %q: %w", s, err)
+}
+
 func toGitInfo(entry string) (*GitInfo, error) {
 	items := strings.Split(entry, "\x1f")
 	if len(items) == 7 {
 		items = append(items, "")
 	}
-	authorDate, err := time.Parse("2006-01-02 15:04:05 -0700", items[5])
+	authorDate, err := parseGitDate(items[5])
 	if err != nil {
 		return nil, err
 	}
-	commitDate, err := time.Parse("2006-01-02 15:04:05 -0700", items[6])
+	commitDate, err := parseGitDate(items[6])
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,129 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+)
+
+// InfoSource supplies supplementary GitInfo for a file, looked up by the
+// same path key used in GitMap. Options.Overlays is consulted in order
+// after the git log has been parsed; the first source with a match for a
+// given file is merged into that file's GitInfo with GitInfo.Merge.
+type InfoSource interface {
+	Lookup(path string) (GitInfo, bool)
+}
+
+// Merge incorporates o, typically looked up from an InfoSource, into gi:
+// gi.FromGetJson is set to o, and gi's MergeCreateDate/MergeUpdateDate are
+// widened to o's CreateDate/AuthorDate when those are earlier than gi's
+// CreateDate or later than gi's AuthorDate, the same rule Map has
+// always applied when reconciling the git log with an overlay.
+func (gi *GitInfo) Merge(o GitInfo) {
+	gi.FromGetJson = &o
+	if o.CreateDate.Before(gi.CreateDate) {
+		gi.MergeCreateDate = o.CreateDate
+	}
+	if o.AuthorDate.After(gi.AuthorDate) {
+		gi.MergeUpdateDate = o.AuthorDate
+	}
+}
+
+// FileInfoSource reads a single JSON file of path -> GitInfo, such as the
+// contentGitInfo.json previously hard-coded into Map. A missing file is not
+// an error; it is simply treated as an empty source, but any other read or
+// decode error is reported to Logf, if set.
+type FileInfoSource struct {
+	Filename string
+	Logf     func(format string, args ...interface{})
+
+	loaded bool
+	data   ContentGitInfo
+}
+
+func (s *FileInfoSource) Lookup(path string) (GitInfo, bool) {
+	if !s.loaded {
+		s.loaded = true
+		if FileExists(s.Filename) {
+			data, err := ReadJSONFile(s.Filename)
+			if err != nil && s.Logf != nil {
+				s.Logf("gitmap: overlay %s: %v", s.Filename, err)
+			}
+			s.data = data
+		}
+	}
+	gi, ok := s.data[path]
+	return gi, ok
+}
+
+// FSInfoSource reads path -> GitInfo from a JSON file inside an fs.FS, e.g.
+// an embed.FS shipped alongside the binary.
+type FSInfoSource struct {
+	FS       fs.FS
+	Filename string
+	Logf     func(format string, args ...interface{})
+
+	loaded bool
+	data   ContentGitInfo
+}
+
+func (s *FSInfoSource) Lookup(path string) (GitInfo, bool) {
+	if !s.loaded {
+		s.loaded = true
+		data, err := fs.ReadFile(s.FS, s.Filename)
+		if err == nil {
+			err = json.Unmarshal(data, &s.data)
+		}
+		if err != nil && s.Logf != nil {
+			s.Logf("gitmap: overlay %s: %v", s.Filename, err)
+		}
+	}
+	gi, ok := s.data[path]
+	return gi, ok
+}
+
+// HTTPInfoSource fetches path -> GitInfo from a URL, e.g. a build service
+// that tracks content metadata separately from the Git history. It is
+// fetched once, on the first Lookup call.
+type HTTPInfoSource struct {
+	URL    string
+	Token  string // sent as a bearer token, if set
+	Client *http.Client
+	Cache  *HTTPCache // optional ETag cache, shared with a Forge if desired
+	Logf   func(format string, args ...interface{})
+
+	loaded bool
+	data   ContentGitInfo
+}
+
+func (s *HTTPInfoSource) Lookup(path string) (GitInfo, bool) {
+	if !s.loaded {
+		s.loaded = true
+		var data ContentGitInfo
+		if err := httpGetJSON(s.Client, s.URL, s.Token, "", s.Cache, &data); err != nil {
+			if s.Logf != nil {
+				s.Logf("gitmap: overlay %s: %v", s.URL, err)
+			}
+		} else {
+			s.data = data
+		}
+	}
+	gi, ok := s.data[path]
+	return gi, ok
+}
+
+// lookupOverlay returns the GitInfo for path from the first source in
+// overlays that has one.
+func lookupOverlay(overlays []InfoSource, path string) (GitInfo, bool) {
+	for _, src := range overlays {
+		if gi, ok := src.Lookup(path); ok {
+			return gi, true
+		}
+	}
+	return GitInfo{}, false
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// FileGitInfo is a single filename/GitInfo pair emitted by MapStream.
+type FileGitInfo struct {
+	Filename string
+	Info     *GitInfo
+}
+
+// MapStream behaves like Map, but emits results incrementally on the
+// returned channel instead of buffering the whole git log output in
+// memory. It still keeps one GitInfo per file seen so far, to merge
+// repeat sightings of a file. Map is in fact implemented as a thin
+// collector over MapStream. This is intended for repositories with very
+// large histories, where Map's first-byte latency and memory footprint
+// become a problem.
+//
+// opts.Backend and opts.Overlays are honoured exactly as Map honours them;
+// only opts.Backend == execBackend{} (the default) gets genuine streaming
+// from the underlying git process, since it's the only Backend that can
+// produce output incrementally. Other backends still return their full
+// result up front, but are then drained through the same record parsing
+// and overlay merge as execBackend, so callers see identical GitInfo
+// either way.
+//
+// If opts.Context is set, it bounds the stream: cancelling it stops the
+// underlying git process (for execBackend) and the goroutine feeding out,
+// instead of leaving both blocked on a consumer that stopped draining.
+//
+// The returned error channel receives at most one error, after which both
+// channels are closed. Callers should drain out until it closes, then check
+// errc.
+func MapStream(opts Options) (<-chan FileGitInfo, <-chan error) {
+	if opts.Backend == nil {
+		opts.Backend = execBackend{}
+	}
+	if opts.Overlays == nil {
+		targetPath := filepath.Join(filepath.Dir(opts.Repository), "assets", "git-info", "contentGitInfo.json")
+		opts.Overlays = []InfoSource{&FileInfoSource{Filename: targetPath, Logf: opts.Logf}}
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	out := make(chan FileGitInfo)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		defer close(errc)
+
+		reader, err := backendReader(ctx, opts)
+		if err != nil {
+			sendErr(ctx, errc, err)
+			return
+		}
+		defer reader.Close()
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		scanner.Split(splitOnRecordSeparator)
+
+		m := make(GitMap)
+		for scanner.Scan() {
+			lines := strings.SplitN(scanner.Text(), "\x1d", 2)
+			if len(lines) != 2 {
+				continue
+			}
+
+			for _, filename := range strings.Split(lines[1], "\n") {
+				filename = strings.TrimSpace(filename)
+				if filename == "" {
+					continue
+				}
+
+				gitInfo, err := toGitInfo(lines[0])
+				if err != nil {
+					sendErr(ctx, errc, err)
+					return
+				}
+
+				info, ok := m[filename]
+				if !ok {
+					info = gitInfo
+					m[filename] = info
+				} else {
+					info.CreateDate = gitInfo.AuthorDate
+					info.MergeCreateDate = gitInfo.AuthorDate
+				}
+				if jsonInfo, exists := lookupOverlay(opts.Overlays, filename); exists {
+					info.Merge(jsonInfo)
+				}
+				info.Year = info.MergeCreateDate.Format("2006")
+
+				select {
+				case out <- FileGitInfo{Filename: filename, Info: info}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			sendErr(ctx, errc, err)
+		}
+	}()
+
+	return out, errc
+}
+
+// sendErr delivers err on errc unless ctx is already done, in which case a
+// consumer that abandoned the stream is presumably not waiting on errc
+// either.
+func sendErr(ctx context.Context, errc chan<- error, err error) {
+	select {
+	case errc <- err:
+	case <-ctx.Done():
+	}
+}
+
+// backendReader returns a Reader over the backend's raw log output.
+// execBackend streams directly from the underlying git process and honours
+// ctx cancellation by aborting it; other backends fetch their (already
+// buffered) result up front, so ctx only governs delivery to the consumer
+// from that point on.
+func backendReader(ctx context.Context, opts Options) (io.ReadCloser, error) {
+	if eb, ok := opts.Backend.(execBackend); ok {
+		return eb.logEntriesPipe(ctx, opts)
+	}
+	out, err := opts.Backend.LogEntries(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(out)), nil
+}
+
+// splitOnRecordSeparator is a bufio.SplitFunc that splits on the "\x1e"
+// record separator used by the git log --format in execBackend.
+func splitOnRecordSeparator(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\x1e'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
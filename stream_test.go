@@ -0,0 +1,37 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestSplitOnRecordSeparator(t *testing.T) {
+	const input = "\x1erecord-one\x1erecord-two\x1erecord-three"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(splitOnRecordSeparator)
+
+	var got []string
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	want := []string{"", "record-one", "record-two", "record-three"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d tokens %q, want %d tokens %q", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
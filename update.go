@@ -0,0 +1,211 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// pushPayload is the subset of a Gogs/Gitea/GitHub push webhook body that
+// UpdateFromPushPayload needs.
+type pushPayload struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+	Ref    string `json:"ref"`
+
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// FileChange describes a single file whose GitInfo was added or refreshed
+// by a call to Update or UpdateFromPushPayload. It is sent on
+// GitRepo.Changes, if set.
+type FileChange struct {
+	Filename string
+	Info     *GitInfo
+}
+
+// Update patches r.Files with the commits in sinceRev..r's revision,
+// instead of rebuilding the whole map. It is meant for long-lived
+// processes, such as a site server, that want to keep a GitMap current as
+// new commits land without re-scanning the full history each time.
+func (r *GitRepo) Update(ctx context.Context, sinceRev string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	opts := r.opts
+	rev := opts.Revision
+	if rev == "" {
+		rev = "HEAD"
+	}
+	opts.Revision = fmt.Sprintf("%s..%s", sinceRev, rev)
+
+	backend := opts.Backend
+	if backend == nil {
+		backend = execBackend{}
+	}
+
+	out, err := backend.LogEntries(opts)
+	if err != nil {
+		return err
+	}
+
+	return r.applyUpdate(opts.Overlays, out)
+}
+
+// UpdateFromPushPayload accepts the JSON body of a Gogs/Gitea/GitHub push
+// webhook, and re-runs the log for payload.Before..payload.After scoped to
+// the files the push touched, patching only those entries in r.Files.
+func (r *GitRepo) UpdateFromPushPayload(payload []byte) error {
+	var p pushPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("gitmap: decode push payload: %w", err)
+	}
+	if p.Before == "" || p.After == "" {
+		return fmt.Errorf("gitmap: push payload is missing before/after")
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, c := range p.Commits {
+		for _, group := range [][]string{c.Added, c.Removed, c.Modified} {
+			for _, path := range group {
+				if !seen[path] {
+					seen[path] = true
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	opts := r.opts
+	opts.Revision = fmt.Sprintf("%s..%s", p.Before, p.After)
+	opts.Pathspecs = paths
+
+	backend := opts.Backend
+	if backend == nil {
+		backend = execBackend{}
+	}
+
+	out, err := backend.LogEntries(opts)
+	if err != nil {
+		return err
+	}
+
+	return r.applyUpdate(opts.Overlays, out)
+}
+
+// applyUpdate parses the raw log output of a scoped "before..after" query
+// and patches it into r.Files under r.mu, then notifies r.Changes of every
+// file touched.
+func (r *GitRepo) applyUpdate(overlays []InfoSource, out string) error {
+	entriesStr := strings.Trim(out, "\n\x1e'")
+	if entriesStr == "" {
+		return nil
+	}
+	entries := strings.Split(entriesStr, "\x1e")
+
+	r.mu.Lock()
+	if r.Files == nil {
+		r.Files = make(GitMap)
+	}
+
+	seenThisBatch := make(map[string]bool)
+	var changes []FileChange
+
+	for _, e := range entries {
+		lines := strings.Split(e, "\x1d")
+		filenames := strings.Split(lines[1], "\n")
+
+		gitInfo, err := toGitInfo(lines[0])
+		if err != nil {
+			r.mu.Unlock()
+			return err
+		}
+
+		for _, filename := range filenames {
+			filename = strings.TrimSpace(filename)
+			if filename == "" {
+				continue
+			}
+
+			first := !seenThisBatch[filename]
+			info := updateFileInfo(r.Files, seenThisBatch, overlays, gitInfo, filename)
+			if first {
+				changes = append(changes, FileChange{Filename: filename, Info: info})
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if r.Changes != nil {
+		for _, c := range changes {
+			select {
+			case r.Changes <- c:
+			default:
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateFileInfo applies a single log record — already scoped to commits
+// made since files was last built or updated — for filename to files.
+//
+// This deliberately does not reuse the full-walk merge that MapStream
+// applies: that merge moves newest-first through the *entire* history, so
+// it treats every repeat sighting of a file as strictly older and always
+// overwrites CreateDate/MergeCreateDate with the new record's AuthorDate.
+// Here, every record is guaranteed to be newer than whatever files already
+// holds for filename, so the same rule would clobber an existing file's
+// true (older) CreateDate with the date of a commit that is actually
+// newer, and it would never refresh the file's latest-commit fields
+// (Hash, Subject, Author...) at all. Instead:
+//
+//   - the first sighting of filename in this batch becomes (or replaces)
+//     the file's latest-commit fields; if filename is new to files, it
+//     also seeds CreateDate, exactly as the full walk would for a brand
+//     new file.
+//   - a later (older, since the log is newest-first) sighting of the same
+//     filename within the same batch only ever widens CreateDate further
+//     back, same as the full walk.
+func updateFileInfo(files GitMap, seenThisBatch map[string]bool, overlays []InfoSource, gitInfo *GitInfo, filename string) *GitInfo {
+	existing, known := files[filename]
+
+	switch {
+	case known && !seenThisBatch[filename]:
+		createDate, mergeCreateDate := existing.CreateDate, existing.MergeCreateDate
+		*existing = *gitInfo
+		existing.CreateDate, existing.MergeCreateDate = createDate, mergeCreateDate
+	case !known && !seenThisBatch[filename]:
+		files[filename] = gitInfo
+		existing = gitInfo
+	default:
+		if gitInfo.AuthorDate.Before(existing.CreateDate) {
+			existing.CreateDate = gitInfo.AuthorDate
+			existing.MergeCreateDate = gitInfo.AuthorDate
+		}
+	}
+	seenThisBatch[filename] = true
+
+	if jsonInfo, exists := lookupOverlay(overlays, filename); exists {
+		existing.Merge(jsonInfo)
+	}
+	existing.Year = existing.MergeCreateDate.Format("2006")
+
+	return existing
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Bjørn Erik Pedersen <bjorn.erik.pedersen@gmail.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package gitmap
+
+import (
+	"strings"
+	"testing"
+)
+
+// buildGitInfo constructs a *GitInfo the same way applyUpdate does: by
+// running a git-log-formatted record through toGitInfo, so CreateDate and
+// the other derived fields are seeded exactly as production code would.
+func buildGitInfo(t *testing.T, hash, dateStr string) *GitInfo {
+	t.Helper()
+	entry := strings.Join([]string{hash, "abbrev", "subject", "Author", "author@example.com", dateStr, dateStr, ""}, "\x1f")
+	gi, err := toGitInfo(entry)
+	if err != nil {
+		t.Fatalf("toGitInfo: %v", err)
+	}
+	return gi
+}
+
+func TestUpdateFileInfoRefreshesExistingFileWithoutClobberingCreateDate(t *testing.T) {
+	files := GitMap{
+		"a.txt": buildGitInfo(t, "old", "2020-01-01 00:00:00 +0000"),
+	}
+	seen := make(map[string]bool)
+
+	newCommit := buildGitInfo(t, "new", "2024-06-01 00:00:00 +0000")
+
+	got := updateFileInfo(files, seen, nil, newCommit, "a.txt")
+
+	if got.Hash != "new" {
+		t.Errorf("Hash = %q, want %q (latest commit should win)", got.Hash, "new")
+	}
+	wantCreateDate := buildGitInfo(t, "old", "2020-01-01 00:00:00 +0000").CreateDate
+	if !got.CreateDate.Equal(wantCreateDate) {
+		t.Errorf("CreateDate = %v, want the original, older date to survive", got.CreateDate)
+	}
+}
+
+func TestUpdateFileInfoSeedsNewFile(t *testing.T) {
+	files := make(GitMap)
+	seen := make(map[string]bool)
+
+	newCommit := buildGitInfo(t, "new", "2024-06-01 00:00:00 +0000")
+
+	got := updateFileInfo(files, seen, nil, newCommit, "new.txt")
+
+	if got != files["new.txt"] {
+		t.Errorf("updateFileInfo did not register the new file in files")
+	}
+	if !got.CreateDate.Equal(newCommit.AuthorDate) {
+		t.Errorf("CreateDate = %v, want the new commit's AuthorDate for a brand new file", got.CreateDate)
+	}
+}